@@ -0,0 +1,149 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "sync"
+
+// FullQueuePolicy selects what a Broadcaster does when a watcher's queue is full at the time an event is published.
+type FullQueuePolicy int
+
+const (
+	// WaitIfChannelFull grows the watcher's queue without bound, so Action never blocks and
+	// nothing is ever dropped; the watcher simply falls arbitrarily far behind. queueLength is
+	// ignored for this policy: Action pushes while holding the Broadcaster's lock, so waiting
+	// for room would stall every other watcher.
+	WaitIfChannelFull FullQueuePolicy = iota
+	// DropIfChannelFull discards the event for that watcher instead of waiting, leaving other watchers unaffected.
+	DropIfChannelFull
+)
+
+func (p FullQueuePolicy) asQueuePolicy() queuePolicy {
+	switch p {
+	case DropIfChannelFull:
+		return queueDropNewest
+	default:
+		return queueUnbounded
+	}
+}
+
+// Broadcaster multiplexes a single stream of WatchEvents to any number of independent watchers.
+// It mirrors ConcurrentObservable's locking: a single mutex guards registration while each
+// watcher delivers out of band via its own subQueue (see queue.go), so one slow consumer
+// cannot stall the publisher or any other watcher.
+type Broadcaster struct {
+	mu          sync.Mutex
+	watchers    map[uint64]*BroadcastWatcher
+	nextID      uint64
+	policy      FullQueuePolicy
+	queueLength int
+}
+
+// NewBroadcaster constructs a Broadcaster whose watchers each buffer up to queueLength
+// undelivered events (0 means unbounded) and apply policy once that buffer is full.
+// With WaitIfChannelFull, queueLength is ignored and every watcher's buffer is unbounded.
+func NewBroadcaster(queueLength int, policy FullQueuePolicy) *Broadcaster {
+	return &Broadcaster{
+		watchers:    make(map[uint64]*BroadcastWatcher),
+		policy:      policy,
+		queueLength: queueLength,
+	}
+}
+
+// Watch registers a new, unfiltered BroadcastWatcher that receives every published event.
+func (b *Broadcaster) Watch() *BroadcastWatcher {
+	return b.watch(nil)
+}
+
+// WatchFiltered registers a new BroadcastWatcher that only receives events for which filter
+// returns true, mirroring watch/filter.go's predicate-based filtering.
+func (b *Broadcaster) WatchFiltered(filter func(Key, WatchEvent) bool) *BroadcastWatcher {
+	return b.watch(filter)
+}
+
+func (b *Broadcaster) watch(filter func(Key, WatchEvent) bool) *BroadcastWatcher {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	w := newBroadcastWatcher(id, b, filter, b.policy, b.queueLength)
+	b.watchers[id] = w
+	go w.q.pump()
+	return w
+}
+
+// Action publishes evt to every registered watcher whose filter accepts it. It never blocks,
+// since neither FullQueuePolicy makes a push wait for room, and never spawns a goroutine per
+// call; see queue.go.
+func (b *Broadcaster) Action(evt WatchEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, w := range b.watchers {
+		if w.filter != nil && !w.filter(evt.Key, evt) {
+			continue
+		}
+		w.q.push(evt)
+	}
+}
+
+// stopWatcher deregisters and closes the watcher with the given id, if it is still registered.
+// Callers must not hold mu.
+func (b *Broadcaster) stopWatcher(id uint64) {
+	b.mu.Lock()
+	w, ok := b.watchers[id]
+	if ok {
+		delete(b.watchers, id)
+	}
+	b.mu.Unlock()
+	if ok {
+		w.q.close()
+	}
+}
+
+// Shutdown stops and closes every registered watcher's channel. Events still queued for a
+// watcher when Shutdown is called are not flushed first and may be discarded.
+// The Broadcaster must not be used afterwards.
+func (b *Broadcaster) Shutdown() {
+	b.mu.Lock()
+	watchers := b.watchers
+	b.watchers = make(map[uint64]*BroadcastWatcher)
+	b.mu.Unlock()
+	for _, w := range watchers {
+		w.q.close()
+	}
+}
+
+// BroadcastWatcher is a single subscriber of a Broadcaster, delivered to out of band via its
+// own subQueue and pump goroutine; see queue.go.
+type BroadcastWatcher struct {
+	id     uint64
+	owner  *Broadcaster
+	filter func(Key, WatchEvent) bool
+	q      *subQueue[WatchEvent]
+}
+
+func newBroadcastWatcher(id uint64, owner *Broadcaster, filter func(Key, WatchEvent) bool, policy FullQueuePolicy, queueLength int) *BroadcastWatcher {
+	return &BroadcastWatcher{
+		id:     id,
+		owner:  owner,
+		filter: filter,
+		q:      newSubQueue[WatchEvent](policy.asQueuePolicy(), queueLength),
+	}
+}
+
+// Updates returns the channel on which this watcher receives events, in order.
+func (w *BroadcastWatcher) Updates() <-chan WatchEvent {
+	return w.q.ch
+}
+
+// Stop detaches the watcher from its Broadcaster and closes its Updates channel.
+// It is safe to call more than once.
+func (w *BroadcastWatcher) Stop() {
+	w.owner.stopWatcher(w.id)
+}
@@ -0,0 +1,52 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "testing"
+
+func TestBroadcasterDeliversInFIFOOrder(t *testing.T) {
+	b := NewBroadcaster(0, WaitIfChannelFull)
+	w := b.Watch()
+	defer w.Stop()
+
+	k := NewPlainKey("configs/db")
+	for i := 0; i < 5; i++ {
+		b.Action(WatchEvent{Type: Modified, Key: k, Value: i})
+	}
+
+	for i := 0; i < 5; i++ {
+		evt := <-w.Updates()
+		if v, ok := evt.Value.(int); !ok || v != i {
+			t.Fatalf("event %d value = %v, want %d", i, evt.Value, i)
+		}
+	}
+}
+
+func TestBroadcasterStopDoesNotRaceWithAction(t *testing.T) {
+	b := NewBroadcaster(0, WaitIfChannelFull)
+	k := NewPlainKey("configs/db")
+	w := b.Watch()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			b.Action(WatchEvent{Type: Modified, Key: k, Value: i})
+		}
+		close(done)
+	}()
+	go func() {
+		for range w.Updates() {
+		}
+	}()
+
+	<-done
+	w.Stop()
+	b.Shutdown()
+}
@@ -0,0 +1,89 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+// DeliveryPolicy selects how a subscription's pump behaves when its queue is full
+// (or, for Coalesce, how repeated notifications are collapsed). It is the public face of
+// the shared queuePolicy used by subQueue; see queue.go.
+type DeliveryPolicy int
+
+const (
+	// Block, with the default bufSize of 0, grows the queue without bound, so Notify never
+	// blocks and nothing is ever dropped; the observer simply falls arbitrarily far behind.
+	// This is the default and matches the unbounded behaviour catchall had before delivery
+	// policies existed. Combined with WithBufferSize(n) for n > 0, it instead gives real
+	// backpressure: Notify waits for the slow observer to make room. That wait happens on the
+	// subscription's own queue, after Notify has released ObserverLock (see Notify), so a
+	// blocked observer stalls only the in-flight Notify call for its key, never unrelated
+	// subscribers or calls.
+	Block DeliveryPolicy = iota
+	// DropOldest discards the longest-queued, not-yet-delivered value to make room for a new one.
+	DropOldest
+	// DropNewest discards the incoming value when the queue is already full, keeping what's queued.
+	DropNewest
+	// Coalesce collapses consecutive, not-yet-delivered notifications into a single queued value.
+	// It is useful for observers that only care that something changed and re-read state on wakeup.
+	Coalesce
+)
+
+func (p DeliveryPolicy) asQueuePolicy(bufSize int) queuePolicy {
+	switch p {
+	case DropOldest:
+		return queueDropOldest
+	case DropNewest:
+		return queueDropNewest
+	case Coalesce:
+		return queueCoalesce
+	case Block:
+		if bufSize > 0 {
+			return queueBlock
+		}
+		return queueUnbounded
+	default:
+		return queueUnbounded
+	}
+}
+
+// ObserveOption configures the queue size and DeliveryPolicy used by Observe.
+type ObserveOption func(*observeConfig)
+
+type observeConfig struct {
+	policy  DeliveryPolicy
+	bufSize int
+}
+
+func defaultObserveConfig() observeConfig {
+	return observeConfig{policy: Block, bufSize: 0}
+}
+
+// WithDeliveryPolicy selects the DeliveryPolicy applied once the subscription's queue reaches bufSize.
+func WithDeliveryPolicy(p DeliveryPolicy) ObserveOption {
+	return func(c *observeConfig) {
+		c.policy = p
+	}
+}
+
+// WithBufferSize bounds the subscription's queue to n pending values. A size of 0 (the
+// default) means unbounded, including for Block. For DropOldest and DropNewest, reaching the
+// bound makes room by discarding a value instead of waiting. For Block, reaching the bound
+// makes Notify wait for the observer to make room; see Block's doc comment.
+func WithBufferSize(n int) ObserveOption {
+	return func(c *observeConfig) {
+		c.bufSize = n
+	}
+}
+
+func newSubscription(id uint64, key string, cfg observeConfig) *subscription {
+	return &subscription{
+		id:  id,
+		key: key,
+		q:   newSubQueue[bool](cfg.policy.asQueuePolicy(cfg.bufSize), cfg.bufSize),
+	}
+}
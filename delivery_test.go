@@ -0,0 +1,65 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockWithBufferSizeAppliesBackpressureWithoutStallingOtherKeys(t *testing.T) {
+	l := NewConcurrentObservable()
+	a := NewPlainKey("a")
+	b := NewPlainKey("b")
+
+	chA, subA := l.Observe(a, WithDeliveryPolicy(Block), WithBufferSize(1))
+	defer subA.Close()
+	chB, subB := l.Observe(b)
+	defer subB.Close()
+
+	l.Notify(a) // picked up by a's pump immediately; send blocks since chA has no reader yet
+	l.Notify(a) // queued: len(queue) == bufSize
+
+	blocked := make(chan struct{})
+	go func() {
+		l.Notify(a) // must wait for room in a's queue
+		close(blocked)
+	}()
+
+	select {
+	case <-blocked:
+		t.Fatal("Notify(a) did not apply backpressure once a's Block queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// An unrelated key must not be stalled by a's backpressure: Notify(a) released
+	// ObserverLock before waiting, so Notify(b) can still proceed and deliver immediately.
+	l.Notify(b)
+	select {
+	case v := <-chB:
+		if !v {
+			t.Fatal("chB received false, want true")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Notify(b) was blocked by an unrelated full Block queue on a")
+	}
+
+	for i := 0; i < 3; i++ {
+		if !<-chA {
+			t.Fatalf("chA value %d was false, want true", i)
+		}
+	}
+
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Fatal("Notify(a) did not unblock once room was made in a's queue")
+	}
+}
@@ -0,0 +1,60 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "strings"
+
+// Labels is a set of string key-value pairs attached to a Key, matched against by Selector.
+type Labels map[string]string
+
+// Labeled is implemented by Key types that carry Labels, so they can be matched by ObserveSelector.
+type Labeled interface {
+	Labels() Labels
+}
+
+// Selector decides whether a set of Labels matches, for use with ObserveSelector.
+type Selector interface {
+	Matches(labels Labels) bool
+}
+
+// LabelSelector is a Selector that matches Labels containing every key-value pair it requires.
+type LabelSelector map[string]string
+
+// Matches reports whether labels contains every key-value pair in sel.
+func (sel LabelSelector) Matches(labels Labels) bool {
+	for k, v := range sel {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// StructuredKey is a Key with a hierarchical path (e.g. "configs/db") and an optional Labels
+// set, for use with ObservePrefix and ObserveSelector respectively.
+type StructuredKey struct {
+	Path []string
+	Set  Labels
+}
+
+// NewStructuredKey constructs a StructuredKey from path segments and an optional label set.
+func NewStructuredKey(labels Labels, path ...string) StructuredKey {
+	return StructuredKey{Path: path, Set: labels}
+}
+
+// String joins the key's path segments with "/", e.g. NewStructuredKey(nil, "configs", "db").String() == "configs/db".
+func (k StructuredKey) String() string {
+	return strings.Join(k.Path, "/")
+}
+
+// Labels returns the key's label set, satisfying Labeled.
+func (k StructuredKey) Labels() Labels {
+	return k.Set
+}
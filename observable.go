@@ -12,10 +12,32 @@ package catchall
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
-type observers map[string][]chan bool
+// subscription is the internal bookkeeping record for a single registered observer.
+// Delivery happens out of band via q's pump goroutine; see queue.go and delivery.go.
+type subscription struct {
+	id        uint64
+	key       string
+	cancelled bool
+	q         *subQueue[bool]
+
+	// predicate is set only for subscribers registered via ObservePrefix/ObserveSelector;
+	// such subscribers live in predicateObservers instead of observers, see Notify.
+	predicate func(k Key) bool
+}
+
+type observers map[string][]*subscription
+
+// eventObservers indexes registered Watchers by key, mirroring observers for the typed event API in watch.go.
+type eventObservers map[string][]*eventSubscription
+
+// ValueAccessor reads the current value stored for k, if any. It is supplied at
+// construction via WithValueAccessor so Observe and Watch can deliver an initial
+// value to a new observer before streaming subsequent changes.
+type ValueAccessor func(k Key) (value any, ok bool)
 
 // ConcurrentObservable enables KeyObservable functionality.
 // It may be used as a mixin to enable observability.
@@ -23,6 +45,43 @@ type ConcurrentObservable struct {
 	DataLock     sync.RWMutex
 	ObserverLock sync.Mutex
 	observers
+	eventObservers
+	// predicateObservers holds ObservePrefix/ObserveSelector subscribers, which cannot be
+	// dispatched via the exact-match observers map. Notify walks it in O(n); see ObservePrefix.
+	predicateObservers []*subscription
+	nextID             uint64
+	valueAccessor      ValueAccessor
+}
+
+// ConcurrentObservableOption configures a ConcurrentObservable at construction time.
+type ConcurrentObservableOption func(*ConcurrentObservable)
+
+// WithValueAccessor installs fn as the ConcurrentObservable's ValueAccessor, enabling
+// initial-value delivery on Observe and Watch/WatchWithContext.
+func WithValueAccessor(fn ValueAccessor) ConcurrentObservableOption {
+	return func(c *ConcurrentObservable) {
+		c.valueAccessor = fn
+	}
+}
+
+// Subscription is a handle to a single registered observer.
+// Close detaches the observer and closes its channel; it is safe to call Close more than once.
+type Subscription struct {
+	id        uint64
+	key       string
+	predicate bool
+	owner     *ConcurrentObservable
+}
+
+// Close removes the subscription's observer from the owning ConcurrentObservable and stops its
+// pump goroutine, which then closes the channel. Values already queued may still be received
+// until the pump observes the close; nothing queued after that is delivered.
+func (s *Subscription) Close() {
+	if s.predicate {
+		s.owner.removePredicate(s.id)
+		return
+	}
+	s.owner.remove(s.key, s.id)
 }
 
 // Key may be any type that can be marshaled into plain-text
@@ -46,37 +105,184 @@ func NewPlainKey(s string) PlainKey {
 // KeyObservable is a collection of key-addressable things, where each key may be observed for changes.
 type KeyObservable interface {
 	// Observe registers a new observer for the given key.
-	// The returned channel will receive a bool each time the observed key changes.
-	Observe(k Key) chan bool
+	// The returned channel will receive a bool each time the observed key changes, delivered
+	// according to the DeliveryPolicy selected via opts (DropOldest/DropNewest/Block/Coalesce).
+	// The returned Subscription can be used to detach the observer again.
+	Observe(k Key, opts ...ObserveOption) (chan bool, *Subscription)
+	// Unsubscribe detaches the observer identified by ch from the given key and closes it.
+	Unsubscribe(k Key, ch chan bool)
 	// Notify notifies all registered observers of the given key of a change to the key.
 	Notify(k Key)
 }
 
-func (l ConcurrentObservable) Observe(k Key) chan bool {
+// Observe registers a new observer for k. If l has a ValueAccessor and k already has a
+// value, the observer's first received value is a synthesized true for it, delivered
+// before any subsequent Notify call for k is observed.
+func (l *ConcurrentObservable) Observe(k Key, opts ...ObserveOption) (chan bool, *Subscription) {
+	cfg := defaultObserveConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	l.ObserverLock.Lock()
+	key := k.String()
+	id := l.nextID
+	l.nextID++
+	sub := newSubscription(id, key, cfg)
+	l.observers[key] = append(l.observers[key], sub)
+
+	if l.valueAccessor != nil {
+		l.DataLock.RLock()
+		if _, ok := l.valueAccessor(k); ok {
+			sub.q.push(true)
+		}
+		l.DataLock.RUnlock()
+	}
+	l.ObserverLock.Unlock()
+
+	go sub.q.pump()
+	return sub.q.ch, &Subscription{id: id, key: key, owner: l}
+}
+
+// ObservePrefix registers an observer for every key that is prefix itself or a descendant of
+// it, e.g. ObservePrefix(NewStructuredKey(nil, "configs", "db")) matches "configs/db/host" but
+// not the unrelated sibling "configs/dbackup". Unlike Observe, matching is predicate-based and
+// walked in O(n) over all prefix/selector subscribers on every Notify, rather than O(1) map
+// lookup; for large key spaces or hot paths, consider a trie-backed prefix index instead.
+func (l *ConcurrentObservable) ObservePrefix(prefix Key, opts ...ObserveOption) (chan bool, *Subscription) {
+	p := prefix.String()
+	return l.observePredicate(func(k Key) bool {
+		s := k.String()
+		return s == p || strings.HasPrefix(s, p+"/")
+	}, opts)
+}
+
+// ObserveSelector registers an observer for every key whose Labels() (see Labeled) satisfy sel.
+// Keys that do not implement Labeled never match. Like ObservePrefix, matching is O(n) per Notify.
+func (l *ConcurrentObservable) ObserveSelector(sel Selector, opts ...ObserveOption) (chan bool, *Subscription) {
+	return l.observePredicate(func(k Key) bool {
+		labeled, ok := k.(Labeled)
+		return ok && sel.Matches(labeled.Labels())
+	}, opts)
+}
+
+func (l *ConcurrentObservable) observePredicate(predicate func(k Key) bool, opts []ObserveOption) (chan bool, *Subscription) {
+	cfg := defaultObserveConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	l.ObserverLock.Lock()
+	id := l.nextID
+	l.nextID++
+	sub := newSubscription(id, "", cfg)
+	sub.predicate = predicate
+	l.predicateObservers = append(l.predicateObservers, sub)
+	l.ObserverLock.Unlock()
+
+	go sub.q.pump()
+	return sub.q.ch, &Subscription{id: id, predicate: true, owner: l}
+}
+
+// removePredicate detaches the predicate-based subscription identified by id, if still registered.
+// Callers must not hold ObserverLock.
+func (l *ConcurrentObservable) removePredicate(id uint64) {
+	l.ObserverLock.Lock()
+	defer l.ObserverLock.Unlock()
+	subs := l.predicateObservers
+	for i, s := range subs {
+		if s.id == id {
+			s.cancelled = true
+			s.q.close()
+			subs[i] = subs[len(subs)-1]
+			l.predicateObservers = subs[:len(subs)-1]
+			return
+		}
+	}
+}
+
+// Unsubscribe detaches the observer registered with channel ch from key k and closes ch.
+// It is provided for symmetry with Observe; prefer the Subscription returned by Observe where possible.
+func (l *ConcurrentObservable) Unsubscribe(k Key, ch chan bool) {
 	l.ObserverLock.Lock()
 	defer l.ObserverLock.Unlock()
-	observer := make(chan bool)
 	key := k.String()
-	l.observers[key] = append(l.observers[key], observer)
-	return observer
+	subs := l.observers[key]
+	for i, s := range subs {
+		if s.q.ch == ch {
+			l.removeAt(key, subs, i)
+			return
+		}
+	}
 }
 
-func (l ConcurrentObservable) Notify(k Key) {
+// remove detaches the subscription identified by (key, id), if it is still registered.
+// Callers must not hold ObserverLock.
+func (l *ConcurrentObservable) remove(key string, id uint64) {
 	l.ObserverLock.Lock()
 	defer l.ObserverLock.Unlock()
-	for _, observer := range l.observers[k.String()] {
-		// The channel may block if no-one is observing
-		go (func() {
-			observer <- true
-		})()
+	subs := l.observers[key]
+	for i, s := range subs {
+		if s.id == id {
+			l.removeAt(key, subs, i)
+			return
+		}
+	}
+}
+
+// removeAt deletes subs[i] by swapping in the last element, marks it cancelled and stops its pump.
+// Callers must hold ObserverLock.
+func (l *ConcurrentObservable) removeAt(key string, subs []*subscription, i int) {
+	subs[i].cancelled = true
+	subs[i].q.close()
+	subs[i] = subs[len(subs)-1]
+	l.observers[key] = subs[:len(subs)-1]
+}
+
+// Notify enqueues true for every observer of k. It never spawns a goroutine per call: each
+// subscription has its own pump goroutine that applies its DeliveryPolicy and forwards to the
+// observer's channel in FIFO order. Matching observers are snapshotted under ObserverLock and
+// pushed to after releasing it, so ObserverLock is never held while a push waits for room
+// (WithDeliveryPolicy(Block) with a bounded WithBufferSize); such a wait stalls this Notify
+// call, including its pushes to the remaining observers of k, but never another call into
+// the ConcurrentObservable.
+func (l *ConcurrentObservable) Notify(k Key) {
+	l.ObserverLock.Lock()
+	var live []*subscription
+	for _, sub := range l.observers[k.String()] {
+		if !sub.cancelled {
+			live = append(live, sub)
+		}
+	}
+	for _, sub := range l.predicateObservers {
+		if !sub.cancelled && sub.predicate(k) {
+			live = append(live, sub)
+		}
+	}
+	l.ObserverLock.Unlock()
+
+	for _, sub := range live {
+		sub.q.push(true)
 	}
 }
 
 // NewConcurrentObservable constructs a new ConcurrentObservable.
-func NewConcurrentObservable() ConcurrentObservable {
-	return ConcurrentObservable{
-		DataLock:     sync.RWMutex{},
-		ObserverLock: sync.Mutex{},
-		observers:    make(observers),
+func NewConcurrentObservable(opts ...ConcurrentObservableOption) ConcurrentObservable {
+	return *newConcurrentObservable(opts...)
+}
+
+// newConcurrentObservable builds a ConcurrentObservable behind a pointer so that applying opts
+// (which take *ConcurrentObservable) never copies the struct's locks; only the final, fully
+// initialized value is copied out, which go vet's copylocks check allows for a function result.
+func newConcurrentObservable(opts ...ConcurrentObservableOption) *ConcurrentObservable {
+	c := &ConcurrentObservable{
+		DataLock:       sync.RWMutex{},
+		ObserverLock:   sync.Mutex{},
+		observers:      make(observers),
+		eventObservers: make(eventObservers),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
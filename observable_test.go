@@ -0,0 +1,78 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "testing"
+
+func TestObserveDeliversInitialValueBeforeNotify(t *testing.T) {
+	values := map[string]any{"configs/db": "present"}
+	l := NewConcurrentObservable(WithValueAccessor(func(k Key) (any, bool) {
+		v, ok := values[k.String()]
+		return v, ok
+	}))
+
+	k := NewPlainKey("configs/db")
+	ch, sub := l.Observe(k)
+	defer sub.Close()
+
+	l.Notify(k)
+	l.Notify(k)
+
+	for i := 0; i < 3; i++ {
+		if !<-ch {
+			t.Fatalf("received value %d was false, want true", i)
+		}
+	}
+}
+
+func TestObservePrefixMatchesOnlyDescendants(t *testing.T) {
+	l := NewConcurrentObservable()
+
+	ch, sub := l.ObservePrefix(NewStructuredKey(nil, "configs", "db"))
+	defer sub.Close()
+
+	l.Notify(NewStructuredKey(nil, "configs", "dbackup"))
+	l.Notify(NewStructuredKey(nil, "configs", "db2"))
+	l.Notify(NewStructuredKey(nil, "configs", "db"))
+	l.Notify(NewStructuredKey(nil, "configs", "db", "host"))
+
+	for i := 0; i < 2; i++ {
+		if !<-ch {
+			t.Fatalf("received value %d was false, want true", i)
+		}
+	}
+
+	select {
+	case <-ch:
+		t.Fatal("ObservePrefix matched a sibling key with the same string prefix")
+	default:
+	}
+}
+
+func TestObserveCloseDoesNotRaceWithNotify(t *testing.T) {
+	l := NewConcurrentObservable()
+	k := NewPlainKey("configs/db")
+	ch, sub := l.Observe(k, WithDeliveryPolicy(DropNewest), WithBufferSize(4))
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			l.Notify(k)
+		}
+		close(done)
+	}()
+	go func() {
+		for range ch {
+		}
+	}()
+
+	<-done
+	sub.Close()
+}
@@ -0,0 +1,147 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "sync"
+
+// queuePolicy is the shared internal representation behind the public DeliveryPolicy
+// (delivery.go) and FullQueuePolicy (broadcaster.go) enums, which both translate to it so
+// that subscription, eventSubscription and BroadcastWatcher can share one pump primitive.
+type queuePolicy int
+
+const (
+	// queueUnbounded grows the queue without bound; bufSize is ignored. push never blocks,
+	// which matters because NotifyEvent and Broadcaster.Action hold a lock shared by every
+	// other subscriber while calling it.
+	queueUnbounded queuePolicy = iota
+	// queueBlock bounds the queue to bufSize and makes push wait for room once it's full,
+	// giving real backpressure. Callers that iterate multiple subscriptions (Notify,
+	// NotifyEvent, Broadcaster.Action) must not hold a lock shared by other subscribers while
+	// calling push with this policy, or a slow consumer would stall every other subscriber.
+	queueBlock
+	// queueDropOldest discards the longest-queued value to make room for a new one once bufSize is reached.
+	queueDropOldest
+	// queueDropNewest discards the incoming value once bufSize is reached, keeping what's queued.
+	queueDropNewest
+	// queueCoalesce collapses consecutive, not-yet-delivered values into a single queued value.
+	queueCoalesce
+)
+
+// subQueue is a per-subscriber FIFO queue with a background pump goroutine that forwards
+// queued values to ch one at a time. Every policy but queueBlock never blocks in push and
+// never spawns a goroutine, so a caller holding a lock shared by other subscribers can never
+// be stalled by a slow or absent consumer. push applies policy/bufSize once the queue is full;
+// pump runs independently and only forwards what is queued.
+type subQueue[T any] struct {
+	ch      chan T
+	policy  queuePolicy
+	bufSize int
+
+	mu    sync.Mutex
+	cond  *sync.Cond
+	queue []T
+	wake  chan struct{}
+	stop  chan struct{}
+}
+
+func newSubQueue[T any](policy queuePolicy, bufSize int) *subQueue[T] {
+	q := &subQueue[T]{
+		ch:      make(chan T),
+		policy:  policy,
+		bufSize: bufSize,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push enqueues v according to policy/bufSize. For queueBlock, it waits until the queue has
+// room or the queue is closed; every other policy never blocks.
+func (q *subQueue[T]) push(v T) {
+	q.mu.Lock()
+	switch q.policy {
+	case queueBlock:
+		for q.bufSize > 0 && len(q.queue) >= q.bufSize {
+			select {
+			case <-q.stop:
+				q.mu.Unlock()
+				return
+			default:
+			}
+			q.cond.Wait()
+		}
+		q.queue = append(q.queue, v)
+	case queueCoalesce:
+		if len(q.queue) > 0 {
+			q.queue[len(q.queue)-1] = v
+		} else {
+			q.queue = append(q.queue, v)
+		}
+	case queueDropNewest:
+		if q.bufSize <= 0 || len(q.queue) < q.bufSize {
+			q.queue = append(q.queue, v)
+		}
+	case queueDropOldest:
+		if q.bufSize > 0 && len(q.queue) >= q.bufSize {
+			q.queue = append(q.queue[1:], v)
+		} else {
+			q.queue = append(q.queue, v)
+		}
+	default: // queueUnbounded
+		q.queue = append(q.queue, v)
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump forwards queued values to ch in FIFO order until the queue is closed. It is the only
+// sender on ch, so it alone closes ch once it observes stop, rather than close() doing so:
+// closing ch from outside this goroutine could race with an in-flight send and panic.
+func (q *subQueue[T]) pump() {
+	defer close(q.ch)
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		}
+		for {
+			q.mu.Lock()
+			if len(q.queue) == 0 {
+				q.mu.Unlock()
+				break
+			}
+			v := q.queue[0]
+			q.queue = q.queue[1:]
+			q.cond.Broadcast() // wake a queueBlock push waiting for room
+			q.mu.Unlock()
+
+			select {
+			case q.ch <- v:
+			case <-q.stop:
+				return
+			}
+		}
+	}
+}
+
+// close stops the pump, which closes ch on its way out, and wakes any push blocked waiting
+// for room so it can observe stop and return. Safe to call at most once.
+func (q *subQueue[T]) close() {
+	close(q.stop)
+	q.mu.Lock()
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
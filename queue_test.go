@@ -0,0 +1,114 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "testing"
+
+func drainSubQueue(t *testing.T, q *subQueue[int], n int) []int {
+	t.Helper()
+	got := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		got = append(got, <-q.ch)
+	}
+	return got
+}
+
+func TestSubQueueUnboundedFIFO(t *testing.T) {
+	q := newSubQueue[int](queueUnbounded, 0)
+	go q.pump()
+	defer q.close()
+
+	for _, v := range []int{1, 2, 3, 4, 5} {
+		q.push(v)
+	}
+
+	got := drainSubQueue(t, q, 5)
+	want := []int{1, 2, 3, 4, 5}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSubQueueDropOldest(t *testing.T) {
+	q := newSubQueue[int](queueDropOldest, 2)
+
+	q.push(1)
+	q.push(2)
+	q.push(3) // should evict 1, queue is now [2, 3]
+
+	go q.pump()
+	defer q.close()
+
+	got := drainSubQueue(t, q, 2)
+	want := []int{2, 3}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSubQueueDropNewest(t *testing.T) {
+	q := newSubQueue[int](queueDropNewest, 2)
+
+	q.push(1)
+	q.push(2)
+	q.push(3) // queue already full, 3 is dropped
+
+	go q.pump()
+	defer q.close()
+
+	got := drainSubQueue(t, q, 2)
+	want := []int{1, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSubQueueCoalesce(t *testing.T) {
+	q := newSubQueue[int](queueCoalesce, 0)
+
+	q.push(1)
+	q.push(2)
+	q.push(3) // collapses onto the single pending value
+
+	go q.pump()
+	defer q.close()
+
+	got := drainSubQueue(t, q, 1)
+	if got[0] != 3 {
+		t.Fatalf("got %v, want [3]", got)
+	}
+}
+
+func TestSubQueueClosePumpDoesNotRaceWithPush(t *testing.T) {
+	q := newSubQueue[int](queueUnbounded, 0)
+	go q.pump()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			q.push(i)
+		}
+		close(done)
+	}()
+
+	go func() {
+		for range q.ch {
+		}
+	}()
+
+	<-done
+	q.close()
+}
@@ -0,0 +1,163 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "context"
+
+// EventType describes what kind of change a WatchEvent carries.
+type EventType int
+
+const (
+	// Added indicates the key now has a value where it previously had none. It is also used
+	// for the initial event synthesized from a ValueAccessor when a Watcher is registered
+	// for a key that already has a value.
+	Added EventType = iota
+	// Modified indicates the key's existing value changed.
+	Modified
+	// Deleted indicates the key's value was removed.
+	Deleted
+)
+
+// WatchEvent carries a single change to a key, including the key itself and the new value, if any.
+type WatchEvent struct {
+	Type  EventType
+	Key   Key
+	Value any
+}
+
+// eventSubscription is the internal bookkeeping record for a single registered Watcher.
+// Delivery happens out of band via q's pump goroutine (see queue.go), so NotifyEvent never
+// blocks and an initial sync event can be queued ahead of it without racing later notifications.
+type eventSubscription struct {
+	id        uint64
+	key       string
+	cancelled bool
+	q         *subQueue[WatchEvent]
+}
+
+func newEventSubscription(id uint64, key string) *eventSubscription {
+	return &eventSubscription{
+		id:  id,
+		key: key,
+		q:   newSubQueue[WatchEvent](queueUnbounded, 0),
+	}
+}
+
+// Watcher streams typed WatchEvents for a single key.
+// Go does not allow type parameters on methods, so Watcher is produced by the free
+// functions Watch and WatchWithContext rather than a method on ConcurrentObservable.
+type Watcher[T any] struct {
+	updates chan WatchEvent
+	ctx     context.Context
+	cancel  context.CancelFunc
+	owner   *ConcurrentObservable
+	key     string
+	id      uint64
+}
+
+// Updates returns the channel on which the watcher receives WatchEvents, in order.
+// If the owning ConcurrentObservable was constructed with a ValueAccessor and the watched
+// key already had a value, the first event delivered is a synthesized Added event carrying it.
+func (w *Watcher[T]) Updates() <-chan WatchEvent {
+	return w.updates
+}
+
+// Value type-asserts evt.Value to T, giving callers of this Watcher[T] a typed accessor
+// instead of reading WatchEvent.Value (any) directly. It reports false if evt did not carry
+// a T, e.g. for a Deleted event with no value.
+func (w *Watcher[T]) Value(evt WatchEvent) (T, bool) {
+	v, ok := evt.Value.(T)
+	return v, ok
+}
+
+// Context returns the context bound to this watcher's lifetime.
+// It is cancelled when Stop is called or, for watchers created via WatchWithContext, when the supplied context is done.
+func (w *Watcher[T]) Context() context.Context {
+	return w.ctx
+}
+
+// Stop detaches the watcher and closes its Updates channel. It is safe to call more than once.
+func (w *Watcher[T]) Stop() {
+	w.cancel()
+	w.owner.removeEvent(w.key, w.id)
+}
+
+// Watch registers a new Watcher[T] for key k on l.
+func Watch[T any](l *ConcurrentObservable, k Key) *Watcher[T] {
+	return WatchWithContext[T](context.Background(), l, k)
+}
+
+// WatchWithContext registers a new Watcher[T] for key k on l, binding its lifetime to ctx.
+// When ctx is done, the watcher is stopped automatically. If l has a ValueAccessor and k
+// already has a value, that value is delivered as an initial Added event before any
+// subsequent NotifyEvent call for k is observed.
+func WatchWithContext[T any](ctx context.Context, l *ConcurrentObservable, k Key) *Watcher[T] {
+	l.ObserverLock.Lock()
+	key := k.String()
+	id := l.nextID
+	l.nextID++
+	sub := newEventSubscription(id, key)
+	l.eventObservers[key] = append(l.eventObservers[key], sub)
+
+	if l.valueAccessor != nil {
+		l.DataLock.RLock()
+		if value, ok := l.valueAccessor(k); ok {
+			sub.q.push(WatchEvent{Type: Added, Key: k, Value: value})
+		}
+		l.DataLock.RUnlock()
+	}
+	l.ObserverLock.Unlock()
+
+	go sub.q.pump()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	w := &Watcher[T]{
+		updates: sub.q.ch,
+		ctx:     watchCtx,
+		cancel:  cancel,
+		owner:   l,
+		key:     key,
+		id:      id,
+	}
+	go func() {
+		<-watchCtx.Done()
+		l.removeEvent(key, id)
+	}()
+	return w
+}
+
+// NotifyEvent notifies all registered Watchers of key k with evt.
+func (l *ConcurrentObservable) NotifyEvent(k Key, evt WatchEvent) {
+	l.ObserverLock.Lock()
+	defer l.ObserverLock.Unlock()
+	for _, sub := range l.eventObservers[k.String()] {
+		if sub.cancelled {
+			continue
+		}
+		sub.q.push(evt)
+	}
+}
+
+// removeEvent detaches the event subscription identified by (key, id), if it is still registered.
+// Callers must not hold ObserverLock.
+func (l *ConcurrentObservable) removeEvent(key string, id uint64) {
+	l.ObserverLock.Lock()
+	defer l.ObserverLock.Unlock()
+	subs := l.eventObservers[key]
+	for i, s := range subs {
+		if s.id == id {
+			s.cancelled = true
+			s.q.close()
+			subs[i] = subs[len(subs)-1]
+			l.eventObservers[key] = subs[:len(subs)-1]
+			return
+		}
+	}
+}
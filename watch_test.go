@@ -0,0 +1,74 @@
+/* Copyright 2020 Noah Hummel
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with the License. You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the specific language governing permissions and limitations under the License.
+ */
+
+package catchall
+
+import "testing"
+
+func TestWatchDeliversInitialValueBeforeNotifyEvent(t *testing.T) {
+	values := map[string]any{"configs/db": "present"}
+	l := NewConcurrentObservable(WithValueAccessor(func(k Key) (any, bool) {
+		v, ok := values[k.String()]
+		return v, ok
+	}))
+
+	k := NewPlainKey("configs/db")
+	w := Watch[string](&l, k)
+	defer w.Stop()
+
+	l.NotifyEvent(k, WatchEvent{Type: Modified, Key: k, Value: "updated"})
+
+	first := <-w.Updates()
+	if first.Type != Added {
+		t.Fatalf("first event type = %v, want Added (synthesized initial value)", first.Type)
+	}
+	if v, ok := w.Value(first); !ok || v != "present" {
+		t.Fatalf("first event value = %v, %v, want \"present\", true", v, ok)
+	}
+
+	second := <-w.Updates()
+	if second.Type != Modified {
+		t.Fatalf("second event type = %v, want Modified", second.Type)
+	}
+	if v, ok := w.Value(second); !ok || v != "updated" {
+		t.Fatalf("second event value = %v, %v, want \"updated\", true", v, ok)
+	}
+}
+
+func TestWatcherValueReportsMismatchedType(t *testing.T) {
+	l := NewConcurrentObservable()
+	k := NewPlainKey("configs/db")
+	w := Watch[int](&l, k)
+	defer w.Stop()
+
+	if _, ok := w.Value(WatchEvent{Type: Added, Key: k, Value: "not an int"}); ok {
+		t.Fatal("Value succeeded for a WatchEvent carrying the wrong type")
+	}
+}
+
+func TestWatchStopDoesNotRaceWithNotifyEvent(t *testing.T) {
+	l := NewConcurrentObservable()
+	k := NewPlainKey("configs/db")
+	w := Watch[string](&l, k)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			l.NotifyEvent(k, WatchEvent{Type: Modified, Key: k, Value: "x"})
+		}
+		close(done)
+	}()
+	go func() {
+		for range w.Updates() {
+		}
+	}()
+
+	<-done
+	w.Stop()
+}